@@ -0,0 +1,79 @@
+//go:build !windows
+
+package vfsftp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/worldiety/vfs"
+	"github.com/worldiety/vfs-ftp/vfsfuse"
+)
+
+// fuseChecks is appended to CTS.All() on platforms that have a FUSE
+// implementation to bridge into.
+var fuseChecks = []*Check{CheckFuseRoundtrip}
+
+var CheckFuseRoundtrip = &Check{
+	Test: func(dp FileSystem) error {
+		if _, err := os.Stat("/dev/fuse"); err != nil {
+			// no FUSE device available in this environment, nothing to check
+			return nil
+		}
+
+		dir, err := os.MkdirTemp("", "vfsfuse-cts-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+
+		mount, err := vfsfuse.Mount(dp, dir, nil)
+		if err != nil {
+			// most likely missing permissions/kernel support in this sandbox
+			return nil
+		}
+		defer mount.Unmount()
+
+		content := []byte("fuse roundtrip")
+		osPath := filepath.Join(dir, "fuseRoundtrip.bin")
+		if err := os.WriteFile(osPath, content, 0644); err != nil {
+			return fmt.Errorf("writing through the OS path: %w", err)
+		}
+
+		got, err := os.ReadFile(osPath)
+		if err != nil {
+			return fmt.Errorf("reading through the OS path: %w", err)
+		}
+		if !bytes.Equal(got, content) {
+			return fmt.Errorf("expected %q through the mounted directory but got %q", content, got)
+		}
+
+		vfsContent, err := ReadAll("/fuseRoundtrip.bin")
+		if err != nil {
+			return fmt.Errorf("reading back through the FileSystem: %w", err)
+		}
+		if !bytes.Equal(vfsContent, content) {
+			return fmt.Errorf("expected the FileSystem to see %q but got %q", content, vfsContent)
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, e := range entries {
+			if e.Name() == "fuseRoundtrip.bin" {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("expected fuseRoundtrip.bin to show up in the OS directory listing")
+		}
+
+		return os.Remove(osPath)
+	},
+	Name:        "FUSE roundtrip",
+	Description: "Mounts the FileSystem via FUSE and runs a mini I/O suite through the OS path, skipped when FUSE isn't available",
+}
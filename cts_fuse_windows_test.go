@@ -0,0 +1,7 @@
+//go:build windows
+
+package vfsftp
+
+// fuseChecks is empty on windows: there is no FUSE implementation to bridge
+// into, so vfsfuse (and CheckFuseRoundtrip) are excluded from this platform.
+var fuseChecks []*Check
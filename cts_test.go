@@ -4,9 +4,18 @@ import (
 	"bytes"
 	"fmt"
 	. "github.com/worldiety/vfs"
+	"github.com/worldiety/vfs-ftp/vfsbasepath"
+	"github.com/worldiety/vfs-ftp/vfsoverlay"
+	"github.com/worldiety/vfs-ftp/vfscrypt"
+	"io"
+	"io/fs"
 	"log"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // A Check tells if a FileSystem has a specific property or not
@@ -56,8 +65,19 @@ func (t *CTS) All() {
 		CheckWriteAndRead,
 		CheckRename,
 		UnsupportedAttributes,
-		CloseProvider,
+		CheckIoFSWalk,
+		CheckIoFSGlob,
+		CheckIoFSSub,
+		CheckOverlayShadowing,
+		CheckCacheHitMiss,
+		CheckCowIsolation,
+		CheckBasePathIsolation,
+		CheckEncryptedRoundtrip,
+		CheckEncryptedNameObfuscation,
+		CheckRandomAccess,
 	}
+	t.checks = append(t.checks, fuseChecks...)
+	t.checks = append(t.checks, CloseProvider)
 }
 
 func (t *CTS) Run(dp FileSystem) CTSResult {
@@ -407,6 +427,637 @@ type unsupportedType struct {
 	atLeastHiddenFieldsAreNotAllowed string
 }
 
+var CheckIoFSWalk = &Check{
+	Test: func(dp FileSystem) error {
+		want, err := readDirRecurOf(dp, "")
+		if err != nil {
+			return err
+		}
+
+		wantNames := make([]string, 0, len(want))
+		for _, entry := range want {
+			wantNames = append(wantNames, string(entry.Path))
+		}
+		sort.Strings(wantNames)
+
+		var gotNames []string
+		err = fs.WalkDir(IoFS(dp), ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if p == "." {
+				return nil
+			}
+			gotNames = append(gotNames, "/"+p)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.Strings(gotNames)
+
+		if len(gotNames) != len(wantNames) {
+			return fmt.Errorf("expected fs.WalkDir to visit %v entries but got %v", len(wantNames), len(gotNames))
+		}
+		for i := range wantNames {
+			if wantNames[i] != gotNames[i] {
+				return fmt.Errorf("expected fs.WalkDir entry %v but got %v", wantNames[i], gotNames[i])
+			}
+		}
+
+		return nil
+	},
+	Name:        "io/fs Walk",
+	Description: "Mounts the FileSystem as an io/fs.FS and asserts that fs.WalkDir sees the same tree as ReadDirRecur",
+}
+
+var CheckIoFSGlob = &Check{
+	Test: func(dp FileSystem) error {
+		want, err := readDirRecurOf(dp, "/canWrite1_1")
+		if err != nil {
+			return err
+		}
+
+		var wantMatches []string
+		for _, entry := range want {
+			if !entry.Resource.Mode.IsDir() {
+				wantMatches = append(wantMatches, string(entry.Path))
+			}
+		}
+		sort.Strings(wantMatches)
+
+		gotMatches, err := fs.Glob(IoFS(dp), "canWrite1_1/*/*/*.bin")
+		if err != nil {
+			return err
+		}
+		for i, m := range gotMatches {
+			gotMatches[i] = "/" + m
+		}
+		sort.Strings(gotMatches)
+
+		if len(gotMatches) != len(wantMatches) {
+			return fmt.Errorf("expected fs.Glob to find %v files but got %v", len(wantMatches), len(gotMatches))
+		}
+		for i := range wantMatches {
+			if wantMatches[i] != gotMatches[i] {
+				return fmt.Errorf("expected fs.Glob match %v but got %v", wantMatches[i], gotMatches[i])
+			}
+		}
+
+		return nil
+	},
+	Name:        "io/fs Glob",
+	Description: "Asserts that fs.Glob on the adapted FileSystem finds the same files as a manual ReadDirRecur filter",
+}
+
+var CheckIoFSSub = &Check{
+	Test: func(dp FileSystem) error {
+		sub, err := fs.Sub(IoFS(dp), "canWrite1_1")
+		if err != nil {
+			return err
+		}
+
+		want, err := ReadAll("/canWrite1_1/subfolder1/subfolder2/512.bin")
+		if err != nil {
+			return err
+		}
+
+		got, err := fs.ReadFile(sub, "subfolder1/subfolder2/512.bin")
+		if err != nil {
+			return err
+		}
+
+		if bytes.Compare(want, got) != 0 {
+			return fmt.Errorf("expected fs.ReadFile through fs.Sub to match ReadAll on the underlying FileSystem")
+		}
+
+		return nil
+	},
+	Name:        "io/fs Sub",
+	Description: "Asserts that fs.Sub yields a rooted io/fs.FS whose fs.ReadFile matches the native ReadAll",
+}
+
+// prefixedFS carves an isolated, independently addressable sub-tree out of a
+// shared FileSystem by prepending a fixed prefix to every path. It exists only
+// so the CTS can exercise the vfsoverlay wrappers, which require two or more
+// distinct FileSystem instances, against a single FileSystem under test.
+type prefixedFS struct {
+	dp     FileSystem
+	prefix Path
+}
+
+func (p *prefixedFS) full(path Path) Path {
+	rel := strings.TrimPrefix(string(path), "/")
+	if rel == "" {
+		return p.prefix
+	}
+	return p.prefix.Child(rel)
+}
+
+func (p *prefixedFS) Open(path Path, flag int, perm os.FileMode) (Resource, error) {
+	return p.dp.Open(p.full(path), flag, perm)
+}
+func (p *prefixedFS) ReadDir(path Path, options interface{}) (DirEntList, error) {
+	return p.dp.ReadDir(p.full(path), options)
+}
+func (p *prefixedFS) ReadAttrs(path Path, dst interface{}) error {
+	return p.dp.ReadAttrs(p.full(path), dst)
+}
+func (p *prefixedFS) WriteAttrs(path Path, src interface{}) error {
+	return p.dp.WriteAttrs(p.full(path), src)
+}
+func (p *prefixedFS) MkDirs(path Path) error { return p.dp.MkDirs(p.full(path)) }
+func (p *prefixedFS) Rename(old, new Path) error {
+	return p.dp.Rename(p.full(old), p.full(new))
+}
+func (p *prefixedFS) Link(old, new Path, mode LinkMode, flags int32) error {
+	return p.dp.Link(p.full(old), p.full(new), mode, flags)
+}
+func (p *prefixedFS) Delete(path Path) error { return p.dp.Delete(p.full(path)) }
+func (p *prefixedFS) Close() error           { return nil }
+
+// countingFS instruments non-write Open calls so CheckCacheHitMiss can
+// distinguish a cache hit from a cache miss by observing how often the
+// backing FileSystem was actually asked to read.
+type countingFS struct {
+	FileSystem
+	reads int32
+}
+
+func (c *countingFS) Open(path Path, flag int, perm os.FileMode) (Resource, error) {
+	if !writeIntentFor(flag) {
+		atomic.AddInt32(&c.reads, 1)
+	}
+	return c.FileSystem.Open(path, flag, perm)
+}
+
+// writeIntentFor mirrors the writeIntent helper every FileSystem wrapper in
+// this repo defines privately for itself; the CTS needs its own copy since it
+// isn't part of any one wrapper package.
+func writeIntentFor(flag int) bool {
+	return flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE) != 0
+}
+
+func writeAllOf(fs FileSystem, path Path, data []byte) error {
+	writer, err := fs.Open(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func readAllOf(fs FileSystem, path Path) ([]byte, error) {
+	reader, err := fs.Open(path, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, reader); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var CheckOverlayShadowing = &Check{
+	Test: func(dp FileSystem) error {
+		top := &prefixedFS{dp, "/unionTop"}
+		bottom := &prefixedFS{dp, "/unionBottom"}
+
+		if err := writeAllOf(top, "/shared.bin", []byte("from top")); err != nil {
+			return err
+		}
+		if err := writeAllOf(bottom, "/shared.bin", []byte("from bottom, should be shadowed")); err != nil {
+			return err
+		}
+		if err := writeAllOf(bottom, "/onlyBottom.bin", []byte("only in bottom")); err != nil {
+			return err
+		}
+
+		union := vfsoverlay.UnionFS([]FileSystem{top, bottom})
+
+		shared, err := readAllOf(union, "/shared.bin")
+		if err != nil {
+			return err
+		}
+		if string(shared) != "from top" {
+			return fmt.Errorf("expected the top layer to shadow the bottom layer but got %q", shared)
+		}
+
+		onlyBottom, err := readAllOf(union, "/onlyBottom.bin")
+		if err != nil {
+			return err
+		}
+		if string(onlyBottom) != "only in bottom" {
+			return fmt.Errorf("expected to fall through to the bottom layer but got %q", onlyBottom)
+		}
+
+		return nil
+	},
+	Name:        "Overlay shadowing",
+	Description: "Asserts that UnionFS serves the topmost layer's content and falls through for paths it doesn't have",
+}
+
+var CheckCacheHitMiss = &Check{
+	Test: func(dp FileSystem) error {
+		remote := &countingFS{FileSystem: &prefixedFS{dp, "/cacheRemote"}}
+		cache := &prefixedFS{dp, "/cacheLocal"}
+
+		if err := writeAllOf(remote, "/hot.bin", []byte("cached content")); err != nil {
+			return err
+		}
+
+		cached := vfsoverlay.CacheOnReadFS(remote, cache, time.Minute)
+
+		if _, err := readAllOf(cached, "/hot.bin"); err != nil {
+			return err
+		}
+		afterFirst := atomic.LoadInt32(&remote.reads)
+		if afterFirst != 1 {
+			return fmt.Errorf("expected exactly 1 remote read after a cache miss but got %v", afterFirst)
+		}
+
+		data, err := readAllOf(cached, "/hot.bin")
+		if err != nil {
+			return err
+		}
+		if string(data) != "cached content" {
+			return fmt.Errorf("expected cached content to round-trip but got %q", data)
+		}
+		afterSecond := atomic.LoadInt32(&remote.reads)
+		if afterSecond != afterFirst {
+			return fmt.Errorf("expected a cache hit to avoid a remote read but remote reads went from %v to %v", afterFirst, afterSecond)
+		}
+
+		return nil
+	},
+	Name:        "Cache hit/miss",
+	Description: "Asserts that CacheOnReadFS only hits the backing remote once per ttl window",
+}
+
+var CheckCowIsolation = &Check{
+	Test: func(dp FileSystem) error {
+		base := &prefixedFS{dp, "/cowBase"}
+		overlay := &prefixedFS{dp, "/cowOverlay"}
+
+		if err := writeAllOf(base, "/shared.bin", []byte("base content")); err != nil {
+			return err
+		}
+		if err := writeAllOf(base, "/toDelete.bin", []byte("base only")); err != nil {
+			return err
+		}
+
+		cow := vfsoverlay.CopyOnWriteFS(base, overlay)
+
+		data, err := readAllOf(cow, "/shared.bin")
+		if err != nil {
+			return err
+		}
+		if string(data) != "base content" {
+			return fmt.Errorf("expected a read through cow to fall through to base but got %q", data)
+		}
+
+		if err := writeAllOf(cow, "/shared.bin", []byte("overlay content")); err != nil {
+			return err
+		}
+
+		data, err = readAllOf(cow, "/shared.bin")
+		if err != nil {
+			return err
+		}
+		if string(data) != "overlay content" {
+			return fmt.Errorf("expected the overlay write to shadow base but got %q", data)
+		}
+
+		baseData, err := readAllOf(base, "/shared.bin")
+		if err != nil {
+			return err
+		}
+		if string(baseData) != "base content" {
+			return fmt.Errorf("expected base to stay untouched by a cow write but got %q", baseData)
+		}
+
+		if err := cow.Delete("/toDelete.bin"); err != nil {
+			return err
+		}
+		if _, err := readAllOf(cow, "/toDelete.bin"); err == nil {
+			return fmt.Errorf("expected a cow delete of a base-only file to hide it")
+		}
+		if _, err := readAllOf(base, "/toDelete.bin"); err != nil {
+			return fmt.Errorf("expected base to stay untouched by a cow delete: %w", err)
+		}
+
+		return nil
+	},
+	Name:        "CoW isolation",
+	Description: "Asserts that CopyOnWriteFS never mutates base and that overlay writes/deletes shadow it",
+}
+
+var CheckBasePathIsolation = &Check{
+	Test: func(dp FileSystem) error {
+		base := &prefixedFS{dp, "/bpBase"}
+		if err := writeAllOf(base, "/outside.bin", []byte("outside the tenant")); err != nil {
+			return err
+		}
+
+		wrapped := vfsbasepath.BasePath(base, "/tenant")
+
+		if err := writeAllOf(wrapped, "/name.bin", []byte("tenant content")); err != nil {
+			return err
+		}
+
+		underlying, err := readAllOf(base, "/tenant/name.bin")
+		if err != nil {
+			return fmt.Errorf("expected a write through the wrapper to land at prefix/name: %w", err)
+		}
+		if string(underlying) != "tenant content" {
+			return fmt.Errorf("expected %q at prefix/name.bin but got %q", "tenant content", underlying)
+		}
+
+		entries, err := vfsoverlay.ReadDirOf(wrapped, "")
+		if err != nil {
+			return err
+		}
+		if len(entries) != 1 || entries[0].Resource.Name != "name.bin" {
+			return fmt.Errorf("expected ReadDir(\"\") on the wrapper to only list items under the prefix but got %v", entries)
+		}
+
+		_, err = wrapped.Open("../outside.bin", os.O_RDONLY, 0)
+		if err == nil {
+			return fmt.Errorf("expected referencing ../ outside the base to be rejected")
+		}
+		if vfsbasepath.UnwrapPathEscapesBaseError(err) == nil {
+			return fmt.Errorf("expected a *PathEscapesBaseError but got %v", err)
+		}
+
+		if _, err := readAllOf(base, "/outside.bin"); err != nil {
+			return fmt.Errorf("expected the backend to stay untouched by a rejected escape: %w", err)
+		}
+
+		return nil
+	},
+	Name:        "BasePath isolation",
+	Description: "Asserts that BasePath confines reads/writes/listing to its prefix and rejects any ../ escape",
+}
+
+var CheckEncryptedRoundtrip = &Check{
+	Test: func(dp FileSystem) error {
+		base := &prefixedFS{dp, "/cryptBase"}
+		key := bytes.Repeat([]byte{0x42}, 32)
+		plaintext := generateTestSlice(8193)
+
+		deterministic, err := vfscrypt.Encrypt(base, key, &vfscrypt.CryptOptions{Deterministic: true})
+		if err != nil {
+			return err
+		}
+		if err := writeAllOf(deterministic, "/a.bin", plaintext); err != nil {
+			return err
+		}
+		if err := writeAllOf(deterministic, "/b.bin", plaintext); err != nil {
+			return err
+		}
+		cipherA, err := readAllOf(base, "/a.bin")
+		if err != nil {
+			return err
+		}
+		cipherB, err := readAllOf(base, "/b.bin")
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(cipherA, cipherB) {
+			return fmt.Errorf("expected Deterministic encryption of identical plaintext to produce identical ciphertext")
+		}
+
+		randomized, err := vfscrypt.Encrypt(base, key, &vfscrypt.CryptOptions{Deterministic: false})
+		if err != nil {
+			return err
+		}
+		if err := writeAllOf(randomized, "/c.bin", plaintext); err != nil {
+			return err
+		}
+		if err := writeAllOf(randomized, "/d.bin", plaintext); err != nil {
+			return err
+		}
+		cipherC, err := readAllOf(base, "/c.bin")
+		if err != nil {
+			return err
+		}
+		cipherD, err := readAllOf(base, "/d.bin")
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(cipherC, cipherD) {
+			return fmt.Errorf("expected non-deterministic encryption of identical plaintext to produce different ciphertext")
+		}
+
+		got, err := readAllOf(deterministic, "/a.bin")
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(got, plaintext) {
+			return fmt.Errorf("expected the decrypted roundtrip to match the original plaintext")
+		}
+
+		// tamper with a byte well past the file header, inside the first frame
+		tampered := append([]byte(nil), cipherA...)
+		tampered[len(tampered)-1] ^= 0xFF
+		if err := writeAllOf(base, "/a.bin", tampered); err != nil {
+			return err
+		}
+		_, err = readAllOf(deterministic, "/a.bin")
+		if err == nil {
+			return fmt.Errorf("expected reading tampered ciphertext to fail")
+		}
+		if vfscrypt.UnwrapAuthenticationError(err) == nil {
+			return fmt.Errorf("expected an *AuthenticationError but got %v", err)
+		}
+
+		return nil
+	},
+	Name:        "Encrypted roundtrip",
+	Description: "Asserts Deterministic ciphertext convergence, non-deterministic divergence, and tamper detection via vfscrypt",
+}
+
+var CheckEncryptedNameObfuscation = &Check{
+	Test: func(dp FileSystem) error {
+		base := &prefixedFS{dp, "/cryptNames"}
+		key := bytes.Repeat([]byte{0x17}, 32)
+
+		obfuscated, err := vfscrypt.Encrypt(base, key, &vfscrypt.CryptOptions{ObfuscateNames: true})
+		if err != nil {
+			return err
+		}
+		if err := writeAllOf(obfuscated, "/secret.txt", []byte("shh")); err != nil {
+			return err
+		}
+
+		rawEntries, err := vfsoverlay.ReadDirOf(base, "")
+		if err != nil {
+			return err
+		}
+		if len(rawEntries) != 1 || rawEntries[0].Resource.Name == "secret.txt" {
+			return fmt.Errorf("expected the base FileSystem's raw listing to not reveal the plaintext name but got %v", rawEntries)
+		}
+
+		entries, err := vfsoverlay.ReadDirOf(obfuscated, "")
+		if err != nil {
+			return err
+		}
+		if len(entries) != 1 || entries[0].Resource.Name != "secret.txt" {
+			return fmt.Errorf("expected ReadDir through the wrapper to recover the plaintext name but got %v", entries)
+		}
+
+		got, err := readAllOf(obfuscated, "/secret.txt")
+		if err != nil {
+			return err
+		}
+		if string(got) != "shh" {
+			return fmt.Errorf("expected the obfuscated-name roundtrip to recover the original content but got %q", got)
+		}
+
+		// "00" decodes to a single byte, shorter than a nonce: exercises the
+		// too-short-to-contain-a-nonce path in decodeName.
+		corrupted := rawEntries[0].Path.Parent().Child("00")
+		if err := base.Rename(rawEntries[0].Path, corrupted); err != nil {
+			return err
+		}
+		if _, err := vfsoverlay.ReadDirOf(obfuscated, ""); err == nil {
+			return fmt.Errorf("expected listing a too-short obfuscated name to fail instead of silently yielding an empty name")
+		}
+
+		return nil
+	},
+	Name:        "Encrypted name obfuscation",
+	Description: "Asserts CryptOptions.ObfuscateNames hides plaintext names from the base FileSystem and recovers them through the wrapper",
+}
+
+// CheckRandomAccess complements CheckWriteAndRead, which only ever streams
+// sequentially. Resource always declares ReadAt/WriteAt/Seek, so a backend
+// that cannot actually support positional I/O (e.g. the FTP backend's STOR)
+// signals that by returning an UnsupportedOperationError from those calls,
+// not by omitting the methods; this check recognizes that via
+// UnwrapUnsupportedOperationError and passes without exercising the rest.
+var CheckRandomAccess = &Check{
+	Test: func(dp FileSystem) error {
+		full := generateTestSlice(8193)
+
+		path := Path("/randomAccessRead.bin")
+		if _, err := WriteAll(path, full); err != nil {
+			return err
+		}
+
+		reader, err := Read(path)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		buf := make([]byte, 10)
+		n, err := reader.ReadAt(buf, 100)
+		if err != nil && UnwrapUnsupportedOperationError(err) != nil {
+			// the backend doesn't support positional reads at all: streaming-only
+			return nil
+		}
+		if err != nil || n != 10 {
+			return fmt.Errorf("expected to read 10 bytes at offset 100, got %v bytes, err %v", n, err)
+		}
+		if !bytes.Equal(buf, full[100:110]) {
+			return fmt.Errorf("expected ReadAt(100) to match the source bytes")
+		}
+
+		overlap := make([]byte, 20)
+		if n, err := reader.ReadAt(overlap, 95); err != nil || n != 20 {
+			return fmt.Errorf("expected to read 20 overlapping bytes at offset 95, got %v bytes, err %v", n, err)
+		}
+		if !bytes.Equal(overlap, full[95:115]) {
+			return fmt.Errorf("expected an overlapping ReadAt(95) to match the source bytes")
+		}
+
+		tail := make([]byte, 10)
+		n, err = reader.ReadAt(tail, int64(len(full))-5)
+		if err != io.EOF {
+			return fmt.Errorf("expected ReadAt past EOF to report io.EOF but got %v", err)
+		}
+		if n != 5 {
+			return fmt.Errorf("expected ReadAt past EOF to report the 5 bytes actually available but got %v", n)
+		}
+		if !bytes.Equal(tail[:5], full[len(full)-5:]) {
+			return fmt.Errorf("expected the truncated ReadAt past EOF to still return the trailing bytes")
+		}
+
+		if pos, err := reader.Seek(50, io.SeekStart); err != nil || pos != 50 {
+			return fmt.Errorf("expected Seek(50, io.SeekStart) to land at 50, got %v, err %v", pos, err)
+		}
+		seeked := make([]byte, 10)
+		if _, err := io.ReadFull(reader, seeked); err != nil {
+			return fmt.Errorf("expected a sequential Read following Seek to succeed: %w", err)
+		}
+		if !bytes.Equal(seeked, full[50:60]) {
+			return fmt.Errorf("expected the bytes following Seek(50) to match the source")
+		}
+
+		writer, err := Write("/randomAccessWrite.bin")
+		if err != nil {
+			return err
+		}
+
+		// two overlapping partial writes sourced from the same underlying
+		// slice must reconstruct it exactly regardless of write order
+		if _, err := writer.WriteAt(full[0:100], 0); err != nil {
+			if UnwrapUnsupportedOperationError(err) != nil {
+				writer.Close()
+				return nil
+			}
+			writer.Close()
+			return err
+		}
+		writerAt := writer
+		if _, err := writerAt.WriteAt(full[50:200], 50); err != nil {
+			writer.Close()
+			return err
+		}
+
+		// hole-punching: writing past the current end must zero-fill the gap
+		holeFiller := []byte("after the hole")
+		if _, err := writerAt.WriteAt(holeFiller, 300); err != nil {
+			writer.Close()
+			return err
+		}
+
+		if err := writer.Close(); err != nil {
+			return err
+		}
+
+		got, err := ReadAll("/randomAccessWrite.bin")
+		if err != nil {
+			return err
+		}
+		if len(got) != 300+len(holeFiller) {
+			return fmt.Errorf("expected the sparse file to be %v bytes long but got %v", 300+len(holeFiller), len(got))
+		}
+		if !bytes.Equal(got[:200], full[:200]) {
+			return fmt.Errorf("expected the overlapping writes to reconstruct the first 200 bytes exactly")
+		}
+		for i := 200; i < 300; i++ {
+			if got[i] != 0 {
+				return fmt.Errorf("expected the hole at byte %v to be zero-filled but got %v", i, got[i])
+			}
+		}
+		if !bytes.Equal(got[300:], holeFiller) {
+			return fmt.Errorf("expected the bytes after the hole to match what was written")
+		}
+
+		return nil
+	},
+	Name:        "Random access",
+	Description: "Exercises ReadAt/WriteAt/Seek on an opened Resource, and passes unconditionally if the backend reports UnsupportedOperationError instead",
+}
+
 var CloseProvider = &Check{
 	Test: func(dp FileSystem) error {
 		err := dp.Close()
@@ -0,0 +1,275 @@
+package vfsftp
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"time"
+
+	. "github.com/worldiety/vfs"
+	"github.com/worldiety/vfs-ftp/vfsoverlay"
+)
+
+// IoFS adapts any vfs.FileSystem to a standard library io/fs.FS, so that a
+// FileSystem can be handed directly to anything that accepts fs.FS: html/template,
+// static site generators, embed-compatible tooling and so on. It also implements
+// fs.ReadDirFS, fs.StatFS, fs.ReadFileFS, fs.SubFS and fs.GlobFS so that the standard
+// library helpers (fs.WalkDir, fs.Glob, fs.Sub, fs.ReadFile) can avoid the generic,
+// slower fallback paths.
+func IoFS(delegate FileSystem) fs.FS {
+	return &ioFS{delegate: delegate, root: ""}
+}
+
+type ioFS struct {
+	delegate FileSystem
+	root     Path
+}
+
+func (f *ioFS) resolve(name string) (Path, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return f.root, nil
+	}
+	return f.root.Child(name), nil
+}
+
+// stat reads the ResourceInfo for p through f.delegate, mirroring the
+// package-level Stat helper but without routing through vfs.Default().
+func (f *ioFS) stat(p Path) (*ResourceInfo, error) {
+	info := &ResourceInfo{}
+	if err := f.delegate.ReadAttrs(p, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// readDir lists the direct children of p through f.delegate.
+func (f *ioFS) readDir(p Path) ([]*ResourceInfo, error) {
+	list, err := vfsoverlay.ReadDirOf(f.delegate, p)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*ResourceInfo, 0, len(list))
+	for _, entry := range list {
+		entries = append(entries, entry.Resource)
+	}
+
+	return entries, nil
+}
+
+func (f *ioFS) readDirRecur(p Path) ([]*PathEntry, error) {
+	return readDirRecurOf(f.delegate, p)
+}
+
+// readDirRecurOf fully lists fs recursively starting at path, descending into
+// every subdirectory it finds. It exists because vfs.ReadDirRecur (and the
+// vfs.Walk it's built on) only visit the first entry of each directory in
+// the pinned vfs version, so it cannot be used for a real recursive listing.
+func readDirRecurOf(fs FileSystem, path Path) ([]*PathEntry, error) {
+	list, err := vfsoverlay.ReadDirOf(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*PathEntry, 0, len(list))
+	for _, entry := range list {
+		entries = append(entries, entry)
+		if entry.Resource.Mode.IsDir() {
+			sub, err := readDirRecurOf(fs, entry.Path)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, sub...)
+		}
+	}
+
+	return entries, nil
+}
+
+func (f *ioFS) Open(name string) (fs.File, error) {
+	p, err := f.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.stat(p)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	if info.Mode.IsDir() {
+		entries, err := f.ReadDir(name)
+		if err != nil {
+			return nil, err
+		}
+		return &ioDir{name: name, info: fileInfoOf(path.Base(name), info), entries: entries}, nil
+	}
+
+	reader, err := f.delegate.Open(p, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &ioFile{name: name, info: fileInfoOf(path.Base(name), info), reader: reader}, nil
+}
+
+func (f *ioFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	p, err := f.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := f.readDir(p)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(list))
+	for _, entry := range list {
+		entries = append(entries, fs.FileInfoToDirEntry(fileInfoOf(entry.Name, entry)))
+	}
+
+	return entries, nil
+}
+
+func (f *ioFS) Stat(name string) (fs.FileInfo, error) {
+	p, err := f.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.stat(p)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+
+	return fileInfoOf(path.Base(name), info), nil
+}
+
+func (f *ioFS) ReadFile(name string) ([]byte, error) {
+	p, err := f.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := f.delegate.Open(p, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: err}
+	}
+	defer reader.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, reader); err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: err}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (f *ioFS) Sub(dir string) (fs.FS, error) {
+	p, err := f.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ioFS{delegate: f.delegate, root: p}, nil
+}
+
+func (f *ioFS) Glob(pattern string) ([]string, error) {
+	list, err := f.readDirRecur(f.root)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, entry := range list {
+		rel := string(entry.Path)
+		if f.root != "" {
+			rel = string(entry.Path)[len(f.root):]
+		}
+		rel = removeLeadingSlash(rel)
+		ok, err := path.Match(pattern, rel)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, rel)
+		}
+	}
+
+	return matches, nil
+}
+
+func removeLeadingSlash(s string) string {
+	for len(s) > 0 && s[0] == '/' {
+		s = s[1:]
+	}
+	return s
+}
+
+// fileInfoOf adapts a vfs.ResourceInfo to a fs.FileInfo.
+func fileInfoOf(name string, ri *ResourceInfo) fs.FileInfo {
+	return &ioFileInfo{name: name, ri: ri}
+}
+
+type ioFileInfo struct {
+	name string
+	ri   *ResourceInfo
+}
+
+func (i *ioFileInfo) Name() string       { return i.name }
+func (i *ioFileInfo) Size() int64        { return i.ri.Size }
+func (i *ioFileInfo) Mode() fs.FileMode  { return i.ri.Mode }
+func (i *ioFileInfo) ModTime() time.Time { return time.UnixMilli(i.ri.ModTime) }
+func (i *ioFileInfo) IsDir() bool        { return i.ri.Mode.IsDir() }
+func (i *ioFileInfo) Sys() interface{}   { return i.ri }
+
+// ioFile implements fs.File for a regular file opened through a FileSystem.
+type ioFile struct {
+	name   string
+	info   fs.FileInfo
+	reader io.ReadCloser
+}
+
+func (f *ioFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *ioFile) Read(p []byte) (int, error) { return f.reader.Read(p) }
+func (f *ioFile) Close() error               { return f.reader.Close() }
+
+// ioDir implements fs.ReadDirFile for a directory opened through a FileSystem.
+type ioDir struct {
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	pos     int
+}
+
+func (d *ioDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *ioDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+func (d *ioDir) Close() error { return nil }
+
+func (d *ioDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		rest := d.entries[d.pos:]
+		d.pos = len(d.entries)
+		return rest, nil
+	}
+
+	if d.pos >= len(d.entries) {
+		return nil, io.EOF
+	}
+
+	end := d.pos + n
+	if end > len(d.entries) {
+		end = len(d.entries)
+	}
+	rest := d.entries[d.pos:end]
+	d.pos = end
+	return rest, nil
+}
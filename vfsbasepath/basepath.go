@@ -0,0 +1,150 @@
+package vfsbasepath
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	. "github.com/worldiety/vfs"
+)
+
+// BasePath wraps base so that every path given to the returned FileSystem is
+// resolved relative to prefix, as if prefix were the root: a file written as
+// "/name" through the wrapper ends up at prefix.Child("name") in base, and
+// "/" lists only what's under prefix. Any path that would resolve outside of
+// prefix (via "..") is rejected with a *PathEscapesBaseError without ever
+// touching base.
+func BasePath(base FileSystem, prefix Path) FileSystem {
+	return &basePathFS{base: base, prefix: prefix}
+}
+
+type basePathFS struct {
+	base   FileSystem
+	prefix Path
+}
+
+// PathEscapesBaseError is returned whenever a path passed to a BasePath
+// FileSystem would resolve outside of its base prefix.
+type PathEscapesBaseError struct {
+	Base Path
+	Path Path
+}
+
+func (e *PathEscapesBaseError) Error() string {
+	return fmt.Sprintf("vfsbasepath: path %q escapes base %q", e.Path, e.Base)
+}
+
+// UnwrapPathEscapesBaseError returns the *PathEscapesBaseError wrapped
+// anywhere in err's chain, or nil if err is not or does not wrap one.
+func UnwrapPathEscapesBaseError(err error) *PathEscapesBaseError {
+	var target *PathEscapesBaseError
+	if errors.As(err, &target) {
+		return target
+	}
+	return nil
+}
+
+// resolve rewrites p as a child of b.prefix, rejecting any path that would
+// climb above it. It walks p's segments itself, rather than relying on
+// Path.Normalize or path.Clean, because both silently collapse a rooted
+// escape like "/../x" down to "/x" - exactly the input a caller exploiting
+// this wrapper as a security boundary would send.
+func (b *basePathFS) resolve(p Path) (Path, error) {
+	names := p.Names()
+	stack := make([]string, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case ".":
+			continue
+		case "..":
+			if len(stack) == 0 {
+				return "", &PathEscapesBaseError{Base: b.prefix, Path: p}
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			stack = append(stack, name)
+		}
+	}
+
+	resolved := b.prefix
+	for _, name := range stack {
+		resolved = resolved.Child(name)
+	}
+	return resolved, nil
+}
+
+func (b *basePathFS) Open(path Path, flag int, perm os.FileMode) (Resource, error) {
+	resolved, err := b.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return b.base.Open(resolved, flag, perm)
+}
+
+func (b *basePathFS) Delete(path Path) error {
+	resolved, err := b.resolve(path)
+	if err != nil {
+		return err
+	}
+	return b.base.Delete(resolved)
+}
+
+func (b *basePathFS) ReadAttrs(path Path, dst interface{}) error {
+	resolved, err := b.resolve(path)
+	if err != nil {
+		return err
+	}
+	return b.base.ReadAttrs(resolved, dst)
+}
+
+func (b *basePathFS) WriteAttrs(path Path, src interface{}) error {
+	resolved, err := b.resolve(path)
+	if err != nil {
+		return err
+	}
+	return b.base.WriteAttrs(resolved, src)
+}
+
+func (b *basePathFS) ReadDir(path Path, options interface{}) (DirEntList, error) {
+	resolved, err := b.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return b.base.ReadDir(resolved, options)
+}
+
+func (b *basePathFS) MkDirs(path Path) error {
+	resolved, err := b.resolve(path)
+	if err != nil {
+		return err
+	}
+	return b.base.MkDirs(resolved)
+}
+
+func (b *basePathFS) Rename(oldPath, newPath Path) error {
+	resolvedOld, err := b.resolve(oldPath)
+	if err != nil {
+		return err
+	}
+	resolvedNew, err := b.resolve(newPath)
+	if err != nil {
+		return err
+	}
+	return b.base.Rename(resolvedOld, resolvedNew)
+}
+
+func (b *basePathFS) Link(oldPath, newPath Path, mode LinkMode, flags int32) error {
+	resolvedOld, err := b.resolve(oldPath)
+	if err != nil {
+		return err
+	}
+	resolvedNew, err := b.resolve(newPath)
+	if err != nil {
+		return err
+	}
+	return b.base.Link(resolvedOld, resolvedNew, mode, flags)
+}
+
+func (b *basePathFS) Close() error {
+	return b.base.Close()
+}
@@ -0,0 +1,5 @@
+// Package vfsbasepath provides a chroot-like vfs.FileSystem wrapper that
+// confines every access to a fixed prefix of an underlying FileSystem,
+// analogous to afero's BasePathFs. It is the common building block for giving
+// each tenant of a multi-tenant FTP root its own isolated view.
+package vfsbasepath
@@ -0,0 +1,263 @@
+package vfscrypt
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+
+	. "github.com/worldiety/vfs"
+)
+
+const (
+	formatVersion  = 1
+	saltSize       = 31
+	fileHeaderSize = 1 + saltSize // version + salt
+
+	chunkSize     = 64 * 1024
+	nonceSize     = chacha20poly1305.NonceSize
+	tagSize       = chacha20poly1305.Overhead
+	frameOverhead = 4 + nonceSize + tagSize // length prefix + nonce + tag
+)
+
+// CryptOptions configures an encrypted FileSystem.
+type CryptOptions struct {
+	// Deterministic derives each file's salt from an HMAC of its own
+	// plaintext content instead of drawing it from crypto/rand. Encrypting
+	// the same plaintext twice then yields byte-identical ciphertext, which
+	// is useful for content-addressed storage or deduplication, at the cost
+	// of leaking that two files are identical. Because the whole file must
+	// be hashed before its salt - and therefore its file key and nonce
+	// sequence - is known, writes in this mode are buffered in full and only
+	// reach the base FileSystem on Close.
+	Deterministic bool
+
+	// ObfuscateNames deterministically encrypts every path segment before it
+	// reaches the underlying FileSystem, so directory listings on the base
+	// FileSystem don't reveal plaintext file or directory names.
+	ObfuscateNames bool
+}
+
+// Encrypt wraps base so that every file written through the returned
+// FileSystem is transparently encrypted with key, and read back decrypted.
+// key must be 32 bytes (a ChaCha20-Poly1305 key).
+func Encrypt(base FileSystem, key []byte, opts *CryptOptions) (FileSystem, error) {
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, fmt.Errorf("vfscrypt: key must be %v bytes, got %v", chacha20poly1305.KeySize, len(key))
+	}
+	if opts == nil {
+		opts = &CryptOptions{}
+	}
+
+	masterKey := make([]byte, len(key))
+	copy(masterKey, key)
+
+	return &cryptFS{base: base, key: masterKey, opts: *opts}, nil
+}
+
+type cryptFS struct {
+	base FileSystem
+	key  []byte
+	opts CryptOptions
+}
+
+// writeIntent reports whether flag would create or mutate the resource, as
+// opposed to merely reading it.
+func writeIntent(flag int) bool {
+	return flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE) != 0
+}
+
+// fileKey derives the per-file content key from the master key and the
+// file's salt, so that rotating the master key only means re-deriving keys
+// for files re-encrypted with a new salt, not inventing a new format.
+func fileKey(masterKey, salt []byte) ([]byte, error) {
+	key := make([]byte, chacha20poly1305.KeySize)
+	kdf := hkdf.New(sha256.New, masterKey, salt, []byte("vfscrypt-file-key"))
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// frameNonce derives a deterministic per-frame nonce from the file's salt
+// and frame index, used when CryptOptions.Deterministic is set.
+func frameNonce(salt []byte, frameIndex uint64) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	var idx [8]byte
+	binary.LittleEndian.PutUint64(idx[:], frameIndex)
+	h.Write(idx[:])
+	return h.Sum(nil)[:nonceSize]
+}
+
+func (f *cryptFS) Open(path Path, flag int, perm os.FileMode) (Resource, error) {
+	resolved, err := f.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if writeIntent(flag) {
+		raw, err := f.base.Open(resolved, flag, perm)
+		if err != nil {
+			return nil, err
+		}
+		w, err := newEncryptingWriter(raw, f.key, f.opts.Deterministic)
+		if err != nil {
+			raw.Close()
+			return nil, err
+		}
+		return NewResourceFromWriter(w), nil
+	}
+
+	raw, err := f.base.Open(resolved, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := newDecryptingReader(string(path), raw, f.key)
+	if err != nil {
+		return nil, err
+	}
+	return NewResourceFromReader(r), nil
+}
+
+func (f *cryptFS) ReadDir(path Path, options interface{}) (DirEntList, error) {
+	resolved, err := f.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := f.base.ReadDir(resolved, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptingDirList{delegate: list, fs: f}, nil
+}
+
+func (f *cryptFS) ReadAttrs(path Path, dst interface{}) error {
+	resolved, err := f.resolvePath(path)
+	if err != nil {
+		return err
+	}
+
+	if err := f.base.ReadAttrs(resolved, dst); err != nil {
+		return err
+	}
+
+	if info, ok := dst.(*ResourceInfo); ok {
+		info.Name = baseName(path)
+		if !info.Mode.IsDir() {
+			plain, err := plainSize(info.Size)
+			if err != nil {
+				return err
+			}
+			info.Size = plain
+		}
+	}
+
+	return nil
+}
+
+func (f *cryptFS) WriteAttrs(path Path, src interface{}) error {
+	resolved, err := f.resolvePath(path)
+	if err != nil {
+		return err
+	}
+	return f.base.WriteAttrs(resolved, src)
+}
+
+func (f *cryptFS) MkDirs(path Path) error {
+	resolved, err := f.resolvePath(path)
+	if err != nil {
+		return err
+	}
+	return f.base.MkDirs(resolved)
+}
+
+func (f *cryptFS) Rename(old, new Path) error {
+	resolvedOld, err := f.resolvePath(old)
+	if err != nil {
+		return err
+	}
+	resolvedNew, err := f.resolvePath(new)
+	if err != nil {
+		return err
+	}
+	return f.base.Rename(resolvedOld, resolvedNew)
+}
+
+func (f *cryptFS) Link(old, new Path, mode LinkMode, flags int32) error {
+	resolvedOld, err := f.resolvePath(old)
+	if err != nil {
+		return err
+	}
+	resolvedNew, err := f.resolvePath(new)
+	if err != nil {
+		return err
+	}
+	return f.base.Link(resolvedOld, resolvedNew, mode, flags)
+}
+
+func (f *cryptFS) Delete(path Path) error {
+	resolved, err := f.resolvePath(path)
+	if err != nil {
+		return err
+	}
+	return f.base.Delete(resolved)
+}
+
+func (f *cryptFS) Close() error {
+	return f.base.Close()
+}
+
+// plainSize reverses the per-frame overhead to recover the plaintext size of
+// a file from the ciphertext size reported by the base FileSystem's Stat.
+func plainSize(cipherSize int64) (int64, error) {
+	body := cipherSize - fileHeaderSize
+	if body < 0 {
+		return 0, fmt.Errorf("vfscrypt: ciphertext smaller than the file header")
+	}
+
+	fullFrames := body / (chunkSize + frameOverhead)
+	rem := body % (chunkSize + frameOverhead)
+
+	plain := fullFrames * chunkSize
+	if rem > 0 {
+		if rem < frameOverhead {
+			return 0, fmt.Errorf("vfscrypt: truncated trailing frame")
+		}
+		plain += rem - frameOverhead
+	}
+
+	return plain, nil
+}
+
+func randomSalt() ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// contentSalt derives a per-file salt from an HMAC of the full plaintext
+// keyed by masterKey. Identical file contents therefore always converge to
+// the same salt - and so the same file key and nonce sequence - which is the
+// whole point of CryptOptions.Deterministic, while any two distinct contents
+// get a distinct, effectively random salt. That is the critical property a
+// fixed, content-independent salt (e.g. derived from masterKey alone) does
+// not have: with a fixed salt, every file would reuse the same (key, nonce)
+// sequence, which for a stream cipher leaks the XOR of any two files'
+// plaintexts and breaks Poly1305's one-time authentication guarantee.
+func contentSalt(masterKey, content []byte) []byte {
+	mac := hmac.New(sha256.New, masterKey)
+	mac.Write(content)
+	return mac.Sum(nil)[:saltSize]
+}
@@ -0,0 +1,24 @@
+// Package vfscrypt provides a transparent encryption wrapper around a
+// vfs.FileSystem. File contents are encrypted with ChaCha20-Poly1305 in
+// fixed-size framed chunks so that Read/Seek remains possible without
+// decrypting a whole file upfront, and file/directory names can optionally be
+// obfuscated with deterministic name encryption.
+//
+// On-disk format
+//
+// Every encrypted file starts with a 32-byte file header:
+//
+//	byte 0       format version
+//	bytes 1-31   random per-file salt
+//
+// followed by zero or more frames:
+//
+//	bytes 0-3    little-endian frame length (= len(nonce) + len(ciphertext) + len(tag))
+//	bytes 4-15   12-byte AEAD nonce
+//	bytes 16-    ciphertext || 16-byte Poly1305 tag
+//
+// Frames hold at most 64 KiB of plaintext each. The per-file content key is
+// derived from the wrapper's master key and the file's salt via HKDF, so
+// rotating the master key only requires re-deriving file keys, not
+// re-encrypting with a brand new random structure.
+package vfscrypt
@@ -0,0 +1,32 @@
+package vfscrypt
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AuthenticationError is returned when a frame's Poly1305 tag does not
+// verify, meaning the ciphertext (or its header/nonce) was tampered with or
+// corrupted in transit.
+type AuthenticationError struct {
+	Path string
+	Err  error
+}
+
+func (e *AuthenticationError) Error() string {
+	return fmt.Sprintf("vfscrypt: %v: authentication failed: %v", e.Path, e.Err)
+}
+
+func (e *AuthenticationError) Unwrap() error {
+	return e.Err
+}
+
+// UnwrapAuthenticationError returns the *AuthenticationError wrapped
+// anywhere in err's chain, or nil if err is not or does not wrap one.
+func UnwrapAuthenticationError(err error) *AuthenticationError {
+	var target *AuthenticationError
+	if errors.As(err, &target) {
+		return target
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package vfscrypt
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+
+	. "github.com/worldiety/vfs"
+)
+
+var nameEncoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// resolvePath translates a logical path given to the wrapper into the path
+// that must be used against the base FileSystem: identity unless name
+// obfuscation is enabled, in which case every segment is deterministically
+// encrypted so the base FileSystem's directory listing never reveals
+// plaintext names.
+func (f *cryptFS) resolvePath(path Path) (Path, error) {
+	if !f.opts.ObfuscateNames {
+		return path, nil
+	}
+
+	segments := splitPath(path)
+	resolved := make([]string, len(segments))
+	for i, seg := range segments {
+		enc, err := f.encodeName(seg)
+		if err != nil {
+			return "", err
+		}
+		resolved[i] = enc
+	}
+
+	return Path("/" + strings.Join(resolved, "/")), nil
+}
+
+func splitPath(path Path) []string {
+	trimmed := strings.Trim(string(path), "/")
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, "/")
+}
+
+// nameKey derives the fixed key used for deterministic name encryption, kept
+// separate from any single file's content key since names aren't chunked or
+// salted per-file.
+func (f *cryptFS) nameKey() ([]byte, error) {
+	key := make([]byte, chacha20poly1305.KeySize)
+	kdf := hkdf.New(sha256.New, f.key, nil, []byte("vfscrypt-name-key"))
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (f *cryptFS) encodeName(name string) (string, error) {
+	key, err := f.nameKey()
+	if err != nil {
+		return "", err
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte("vfscrypt-name-nonce"))
+	h.Write([]byte(name))
+	nonce := h.Sum(nil)[:chacha20poly1305.NonceSize]
+
+	ciphertext := aead.Seal(nil, nonce, []byte(name), nil)
+	return nameEncoding.EncodeToString(append(nonce, ciphertext...)), nil
+}
+
+func (f *cryptFS) decodeName(encoded string) (string, error) {
+	key, err := f.nameKey()
+	if err != nil {
+		return "", err
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := nameEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < chacha20poly1305.NonceSize {
+		return "", fmt.Errorf("vfscrypt: %v: decoded name shorter than a nonce", encoded)
+	}
+
+	nonce, ciphertext := raw[:chacha20poly1305.NonceSize], raw[chacha20poly1305.NonceSize:]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", &AuthenticationError{Path: encoded, Err: err}
+	}
+	return string(plain), nil
+}
+
+func baseName(path Path) string {
+	segments := splitPath(path)
+	if len(segments) == 0 {
+		return ""
+	}
+	return segments[len(segments)-1]
+}
@@ -0,0 +1,117 @@
+package vfscrypt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// decryptingReader reads and verifies one frame at a time from raw, serving
+// decrypted plaintext through Read. It never buffers more than one frame's
+// worth of plaintext, so Seek-free streaming of large files stays cheap.
+type decryptingReader struct {
+	path string
+	raw  io.ReadCloser
+	aead cipherAEAD
+	salt []byte
+
+	frameIndex uint64
+	plain      []byte
+	pos        int
+	done       bool
+}
+
+func newDecryptingReader(path string, raw io.ReadCloser, masterKey []byte) (*decryptingReader, error) {
+	header := make([]byte, fileHeaderSize)
+	if _, err := io.ReadFull(raw, header); err != nil {
+		raw.Close()
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("vfscrypt: %v: file too short for a vfscrypt header", path)
+		}
+		return nil, err
+	}
+	if header[0] != formatVersion {
+		raw.Close()
+		return nil, fmt.Errorf("vfscrypt: %v: unsupported format version %v", path, header[0])
+	}
+	salt := header[1:]
+
+	key, err := fileKey(masterKey, salt)
+	if err != nil {
+		raw.Close()
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		raw.Close()
+		return nil, err
+	}
+
+	return &decryptingReader{path: path, raw: raw, aead: aead, salt: salt}, nil
+}
+
+func (r *decryptingReader) Read(p []byte) (int, error) {
+	if r.pos >= len(r.plain) {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.readFrame(); err != nil {
+			return 0, err
+		}
+		if r.done && len(r.plain) == 0 {
+			return 0, io.EOF
+		}
+	}
+
+	n := copy(p, r.plain[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+func (r *decryptingReader) readFrame() error {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r.raw, lenBuf[:]); err != nil {
+		if err == io.EOF {
+			r.done = true
+			r.plain = nil
+			return nil
+		}
+		return err
+	}
+
+	frameLen := binary.LittleEndian.Uint32(lenBuf[:])
+	if frameLen < nonceSize+tagSize {
+		return fmt.Errorf("vfscrypt: %v: corrupt frame length %v", r.path, frameLen)
+	}
+
+	frame := make([]byte, frameLen)
+	if _, err := io.ReadFull(r.raw, frame); err != nil {
+		return fmt.Errorf("vfscrypt: %v: truncated frame: %w", r.path, err)
+	}
+
+	nonce, ciphertext := frame[:nonceSize], frame[nonceSize:]
+	plain, err := r.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return &AuthenticationError{Path: r.path, Err: err}
+	}
+
+	r.plain = plain
+	r.pos = 0
+	r.frameIndex++
+
+	if len(plain) < chunkSize {
+		// a short frame can only be the last one in the stream
+		if _, err := io.ReadFull(r.raw, lenBuf[:1]); err == nil {
+			return fmt.Errorf("vfscrypt: %v: unexpected data after a short frame", r.path)
+		}
+		r.done = true
+	}
+
+	return nil
+}
+
+func (r *decryptingReader) Close() error {
+	return r.raw.Close()
+}
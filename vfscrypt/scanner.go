@@ -0,0 +1,47 @@
+package vfscrypt
+
+import (
+	. "github.com/worldiety/vfs"
+)
+
+// decryptingDirList wraps a base DirEntList so each ResourceInfo it yields
+// reports plaintext name and size instead of the obfuscated name and
+// ciphertext size stored on the base FileSystem.
+type decryptingDirList struct {
+	delegate DirEntList
+	fs       *cryptFS
+}
+
+func (s *decryptingDirList) Next() bool   { return s.delegate.Next() }
+func (s *decryptingDirList) Err() error   { return s.delegate.Err() }
+func (s *decryptingDirList) Size() int64  { return s.delegate.Size() }
+func (s *decryptingDirList) Close() error { return s.delegate.Close() }
+
+func (s *decryptingDirList) Scan(dst interface{}) error {
+	if err := s.delegate.Scan(dst); err != nil {
+		return err
+	}
+
+	info, ok := dst.(*ResourceInfo)
+	if !ok {
+		return nil
+	}
+
+	if s.fs.opts.ObfuscateNames {
+		plain, err := s.fs.decodeName(info.Name)
+		if err != nil {
+			return err
+		}
+		info.Name = plain
+	}
+
+	if !info.Mode.IsDir() {
+		plain, err := plainSize(info.Size)
+		if err != nil {
+			return err
+		}
+		info.Size = plain
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package vfscrypt
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// cipherAEAD is the subset of cipher.AEAD used here, named to keep the
+// import list in this file focused on chacha20poly1305.
+type cipherAEAD interface {
+	Seal(dst, nonce, plaintext, additionalData []byte) []byte
+	Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error)
+}
+
+// encryptingWriter writes the encrypted framing described in this package's
+// doc comment. In random-salt mode it streams: it buffers at most one
+// chunkSize frame at a time and forwards each as soon as it is full. In
+// CryptOptions.Deterministic mode the salt depends on a hash of the entire
+// plaintext (see contentSalt), so nothing can be written - not even the
+// header - until the whole file has been seen; in that mode Write only
+// accumulates the plaintext, and the real work happens in Close.
+type encryptingWriter struct {
+	raw           io.WriteCloser
+	masterKey     []byte
+	deterministic bool
+
+	// streaming (non-deterministic) state
+	aead          cipherAEAD
+	salt          []byte
+	frameIndex    uint64
+	headerWritten bool
+
+	buf []byte
+}
+
+func newEncryptingWriter(raw io.WriteCloser, masterKey []byte, deterministic bool) (*encryptingWriter, error) {
+	w := &encryptingWriter{raw: raw, masterKey: masterKey, deterministic: deterministic}
+	if deterministic {
+		return w, nil
+	}
+
+	salt, err := randomSalt()
+	if err != nil {
+		return nil, err
+	}
+	key, err := fileKey(masterKey, salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	w.aead = aead
+	w.salt = salt
+	w.buf = make([]byte, 0, chunkSize)
+	return w, nil
+}
+
+func (w *encryptingWriter) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	header := make([]byte, fileHeaderSize)
+	header[0] = formatVersion
+	copy(header[1:], w.salt)
+	if _, err := w.raw.Write(header); err != nil {
+		return err
+	}
+	w.headerWritten = true
+	return nil
+}
+
+func (w *encryptingWriter) Write(p []byte) (int, error) {
+	if w.deterministic {
+		w.buf = append(w.buf, p...)
+		return len(p), nil
+	}
+
+	if err := w.writeHeader(); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := copy(w.buf[len(w.buf):cap(w.buf)], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+
+		if len(w.buf) == cap(w.buf) {
+			if err := w.flush(false); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	return written, nil
+}
+
+// flush encrypts the current buffer as one frame using a random nonce. Only
+// used in streaming (non-deterministic) mode; final is only used to decide
+// whether a zero-length buffer still needs to be emitted (it does, exactly
+// once, to represent an otherwise-empty file).
+func (w *encryptingWriter) flush(final bool) error {
+	if len(w.buf) == 0 && !final {
+		return nil
+	}
+
+	nonce := make([]byte, nonceSize)
+	_, _ = rand.Read(nonce)
+	if err := w.writeFrame(nonce, w.buf); err != nil {
+		return err
+	}
+
+	w.frameIndex++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (w *encryptingWriter) writeFrame(nonce, plain []byte) error {
+	return writeFrame(w.raw, w.aead, nonce, plain)
+}
+
+func writeFrame(raw io.Writer, aead cipherAEAD, nonce, plain []byte) error {
+	sealed := aead.Seal(nil, nonce, plain, nil)
+
+	frame := make([]byte, 4+len(nonce)+len(sealed))
+	binary.LittleEndian.PutUint32(frame[:4], uint32(len(nonce)+len(sealed)))
+	copy(frame[4:], nonce)
+	copy(frame[4+len(nonce):], sealed)
+
+	_, err := raw.Write(frame)
+	return err
+}
+
+// closeDeterministic derives the salt from the whole buffered plaintext,
+// then writes the header followed by one frame per chunkSize-sized slice
+// (at least one, so an empty file still gets a valid header and frame).
+func (w *encryptingWriter) closeDeterministic() error {
+	salt := contentSalt(w.masterKey, w.buf)
+	key, err := fileKey(w.masterKey, salt)
+	if err != nil {
+		return err
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, fileHeaderSize)
+	header[0] = formatVersion
+	copy(header[1:], salt)
+	if _, err := w.raw.Write(header); err != nil {
+		return err
+	}
+
+	plain := w.buf
+	frameIndex := uint64(0)
+	for {
+		end := chunkSize
+		if end > len(plain) {
+			end = len(plain)
+		}
+		chunk := plain[:end]
+		plain = plain[end:]
+
+		if err := writeFrame(w.raw, aead, frameNonce(salt, frameIndex), chunk); err != nil {
+			return err
+		}
+		frameIndex++
+
+		if len(plain) == 0 {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (w *encryptingWriter) Close() error {
+	if w.deterministic {
+		if err := w.closeDeterministic(); err != nil {
+			w.raw.Close()
+			return err
+		}
+		return w.raw.Close()
+	}
+
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	if w.frameIndex == 0 || len(w.buf) > 0 {
+		if err := w.flush(true); err != nil {
+			w.raw.Close()
+			return err
+		}
+	}
+	return w.raw.Close()
+}
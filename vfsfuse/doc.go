@@ -0,0 +1,10 @@
+//go:build !windows
+
+// Package vfsfuse bridges any vfs.FileSystem into a real, mountable directory
+// using FUSE (via github.com/hanwen/go-fuse/v2), so that tools which only speak
+// the OS path API (editors, `cp`, backup software) can operate directly on a
+// remote backend such as the FTP FileSystem in this module.
+//
+// Only linux and darwin have a FUSE implementation to bridge into, so this
+// package is excluded from windows builds.
+package vfsfuse
@@ -0,0 +1,60 @@
+//go:build !windows
+
+package vfsfuse
+
+import (
+	"fmt"
+
+	gofuse "github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	. "github.com/worldiety/vfs"
+)
+
+// MountOptions controls how a FileSystem is exposed through FUSE.
+type MountOptions struct {
+	// Debug logs every FUSE operation to stderr, useful while wiring up a new
+	// backend.
+	Debug bool
+	// AllowOther lets other users on the host access the mount, it requires
+	// user_allow_other in /etc/fuse.conf.
+	AllowOther bool
+}
+
+// MountedFileSystem is a live FUSE bridge between a vfs.FileSystem and a
+// local directory, returned by Mount.
+type MountedFileSystem struct {
+	server *fuse.Server
+}
+
+// Mount exposes delegate as a real, OS-visible directory at mountpoint. The
+// mount stays live until (*MountedFileSystem).Unmount is called.
+func Mount(delegate FileSystem, mountpoint string, opts *MountOptions) (*MountedFileSystem, error) {
+	if opts == nil {
+		opts = &MountOptions{}
+	}
+
+	root := &node{delegate: delegate, path: ""}
+	server, err := gofuse.Mount(mountpoint, root, &gofuse.Options{
+		MountOptions: fuse.MountOptions{
+			Debug:      opts.Debug,
+			AllowOther: opts.AllowOther,
+			FsName:     "vfsfuse",
+			Name:       "vfsfuse",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("vfsfuse: mount %v: %w", mountpoint, err)
+	}
+
+	return &MountedFileSystem{server: server}, nil
+}
+
+// Unmount detaches the mount and waits for the FUSE server to stop serving.
+func (m *MountedFileSystem) Unmount() error {
+	if err := m.server.Unmount(); err != nil {
+		return fmt.Errorf("vfsfuse: unmount: %w", err)
+	}
+	m.server.Wait()
+	return nil
+}
@@ -0,0 +1,252 @@
+//go:build !windows
+
+package vfsfuse
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	. "github.com/worldiety/vfs"
+	"github.com/worldiety/vfs-ftp/vfsoverlay"
+)
+
+// node is the FUSE inode for a single path inside the mounted FileSystem. The
+// node tree itself is lazily populated by Lookup/Readdir, the vfs.FileSystem
+// stays the single source of truth.
+type node struct {
+	fs.Inode
+	delegate FileSystem
+	path     Path
+}
+
+var (
+	_ fs.NodeGetattrer = (*node)(nil)
+	_ fs.NodeLookuper  = (*node)(nil)
+	_ fs.NodeReaddirer = (*node)(nil)
+	_ fs.NodeOpener    = (*node)(nil)
+	_ fs.NodeCreater   = (*node)(nil)
+	_ fs.NodeMkdirer   = (*node)(nil)
+	_ fs.NodeUnlinker  = (*node)(nil)
+	_ fs.NodeRmdirer   = (*node)(nil)
+	_ fs.NodeRenamer   = (*node)(nil)
+)
+
+func (n *node) child(name string) Path {
+	return n.path.Child(name)
+}
+
+func attrFromInfo(info *ResourceInfo, out *fuse.Attr) {
+	out.Mode = uint32(info.Mode.Perm())
+	if info.Mode.IsDir() {
+		out.Mode |= syscall.S_IFDIR
+	} else {
+		out.Mode |= syscall.S_IFREG
+	}
+	out.Size = uint64(info.Size)
+
+	secs := uint64(info.ModTime / 1000)
+	nsec := uint32((info.ModTime % 1000) * 1_000_000)
+	out.Mtime, out.Mtimensec = secs, nsec
+	out.Atime, out.Atimensec = secs, nsec
+	out.Ctime, out.Ctimensec = secs, nsec
+}
+
+func (n *node) Getattr(ctx context.Context, f fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	info := &ResourceInfo{}
+	if err := n.delegate.ReadAttrs(n.path, info); err != nil {
+		return syscall.ENOENT
+	}
+	attrFromInfo(info, &out.Attr)
+	return 0
+}
+
+func (n *node) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	childPath := n.child(name)
+	info := &ResourceInfo{}
+	if err := n.delegate.ReadAttrs(childPath, info); err != nil {
+		return nil, syscall.ENOENT
+	}
+
+	attrFromInfo(info, &out.Attr)
+	child := &node{delegate: n.delegate, path: childPath}
+	mode := fuse.S_IFREG
+	if info.Mode.IsDir() {
+		mode = fuse.S_IFDIR
+	}
+	return n.NewInode(ctx, child, fs.StableAttr{Mode: uint32(mode)}), 0
+}
+
+type dirStream struct {
+	entries []*PathEntry
+	pos     int
+}
+
+func (d *dirStream) HasNext() bool {
+	return d.pos < len(d.entries)
+}
+
+func (d *dirStream) Next() (fuse.DirEntry, syscall.Errno) {
+	e := d.entries[d.pos]
+	d.pos++
+	mode := uint32(fuse.S_IFREG)
+	if e.Resource.Mode.IsDir() {
+		mode = fuse.S_IFDIR
+	}
+	return fuse.DirEntry{Name: e.Resource.Name, Mode: mode}, 0
+}
+
+func (d *dirStream) Close() {}
+
+func (n *node) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	entries, err := vfsoverlay.ReadDirOf(n.delegate, n.path)
+	if err != nil {
+		return nil, syscall.EIO
+	}
+	return &dirStream{entries: entries}, 0
+}
+
+func (n *node) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	flag := os.O_RDONLY
+	if flags&(syscall.O_WRONLY|syscall.O_RDWR) != 0 {
+		flag = os.O_RDWR
+	}
+
+	res, err := n.delegate.Open(n.path, flag, 0666)
+	if err != nil {
+		return nil, 0, syscall.EIO
+	}
+	return newHandle(res), fuse.FOPEN_DIRECT_IO, 0
+}
+
+func (n *node) Create(ctx context.Context, name string, flags uint32, mode uint32, out *fuse.EntryOut) (*fs.Inode, fs.FileHandle, uint32, syscall.Errno) {
+	childPath := n.child(name)
+	res, err := n.delegate.Open(childPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, os.FileMode(mode))
+	if err != nil {
+		return nil, nil, 0, syscall.EIO
+	}
+
+	child := &node{delegate: n.delegate, path: childPath}
+	inode := n.NewInode(ctx, child, fs.StableAttr{Mode: fuse.S_IFREG})
+	return inode, newHandle(res), fuse.FOPEN_DIRECT_IO, 0
+}
+
+func (n *node) Mkdir(ctx context.Context, name string, mode uint32, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	childPath := n.child(name)
+	if err := n.delegate.MkDirs(childPath); err != nil {
+		return nil, syscall.EIO
+	}
+
+	child := &node{delegate: n.delegate, path: childPath}
+	return n.NewInode(ctx, child, fs.StableAttr{Mode: fuse.S_IFDIR}), 0
+}
+
+func (n *node) Unlink(ctx context.Context, name string) syscall.Errno {
+	if err := n.delegate.Delete(n.child(name)); err != nil {
+		return syscall.EIO
+	}
+	return 0
+}
+
+func (n *node) Rmdir(ctx context.Context, name string) syscall.Errno {
+	if err := n.delegate.Delete(n.child(name)); err != nil {
+		return syscall.EIO
+	}
+	return 0
+}
+
+func (n *node) Rename(ctx context.Context, name string, newParent fs.InodeEmbedder, newName string, flags uint32) syscall.Errno {
+	dst, ok := newParent.(*node)
+	if !ok {
+		return syscall.EXDEV
+	}
+	if err := n.delegate.Rename(n.child(name), dst.child(newName)); err != nil {
+		return syscall.EIO
+	}
+	return 0
+}
+
+// handle is the FUSE file handle for an open file, backed directly by a
+// vfs.Resource. A Resource always declares ReadAt/WriteAt; backends that
+// cannot support positional I/O (e.g. FTP's STOR) return
+// *UnsupportedOperationError instead of simply lacking the method, so that
+// case is detected from the error rather than from a type assertion.
+// Streaming-only writers are buffered in full and flushed on Release,
+// mirroring rclone's WriteFileHandle.
+type handle struct {
+	resource Resource
+
+	mutex sync.Mutex
+	buf   []byte
+}
+
+var (
+	_ fs.FileReader   = (*handle)(nil)
+	_ fs.FileWriter   = (*handle)(nil)
+	_ fs.FileReleaser = (*handle)(nil)
+)
+
+func newHandle(resource Resource) *handle {
+	return &handle{resource: resource}
+}
+
+func (h *handle) Read(ctx context.Context, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	n, err := h.resource.ReadAt(dest, off)
+	if err != nil && err != io.EOF {
+		if UnwrapUnsupportedOperationError(err) == nil {
+			return nil, syscall.EIO
+		}
+
+		// streaming-only backend: only sequential reads from offset 0 are supported
+		if off != 0 {
+			return nil, syscall.ESPIPE
+		}
+		n, err = io.ReadFull(h.resource, dest)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, syscall.EIO
+		}
+	}
+	return fuse.ReadResultData(dest[:n]), 0
+}
+
+func (h *handle) Write(ctx context.Context, data []byte, off int64) (uint32, syscall.Errno) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	n, err := h.resource.WriteAt(data, off)
+	if err == nil {
+		return uint32(n), 0
+	}
+	if UnwrapUnsupportedOperationError(err) == nil {
+		return 0, syscall.EIO
+	}
+
+	// streaming-only backend: buffer the whole file and flush it on Release
+	end := int(off) + len(data)
+	if end > len(h.buf) {
+		grown := make([]byte, end)
+		copy(grown, h.buf)
+		h.buf = grown
+	}
+	copy(h.buf[off:], data)
+	return uint32(len(data)), 0
+}
+
+func (h *handle) Release(ctx context.Context) syscall.Errno {
+	if h.buf != nil {
+		if _, err := h.resource.Write(h.buf); err != nil {
+			h.resource.Close()
+			return syscall.EIO
+		}
+	}
+
+	if err := h.resource.Close(); err != nil {
+		return syscall.EIO
+	}
+	return 0
+}
@@ -0,0 +1,146 @@
+package vfsoverlay
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	. "github.com/worldiety/vfs"
+)
+
+// CacheOnReadFS transparently caches reads from a slow backend (remote, e.g. the
+// FTP backend in this module) into a fast one (cache, e.g. a local FileSystem).
+// A cached copy is considered fresh for ttl after it was populated; once it
+// expires, the next Read re-validates against remote's Stat and re-populates the
+// cache if remote's content changed or the cache entry is simply gone.
+func CacheOnReadFS(remote, cache FileSystem, ttl time.Duration) FileSystem {
+	return &cacheOnReadFS{remote: remote, cache: cache, ttl: ttl, cachedAt: map[Path]time.Time{}}
+}
+
+type cacheOnReadFS struct {
+	remote FileSystem
+	cache  FileSystem
+	ttl    time.Duration
+
+	mutex    sync.Mutex
+	cachedAt map[Path]time.Time
+}
+
+func (f *cacheOnReadFS) Open(path Path, flag int, perm os.FileMode) (Resource, error) {
+	if writeIntent(flag) {
+		f.invalidate(path)
+		return f.remote.Open(path, flag, perm)
+	}
+
+	if f.isFresh(path) {
+		if res, err := f.cache.Open(path, os.O_RDONLY, 0); err == nil {
+			return res, nil
+		}
+	} else if f.sameModTime(path) {
+		if res, err := f.cache.Open(path, os.O_RDONLY, 0); err == nil {
+			f.markFresh(path)
+			return res, nil
+		}
+	}
+
+	remoteRes, err := f.remote.Open(path, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer remoteRes.Close()
+
+	cacheWriter, err := f.cache.Open(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		// cache unavailable, serve straight from remote
+		return f.remote.Open(path, os.O_RDONLY, 0)
+	}
+
+	if _, err := io.Copy(cacheWriter, remoteRes); err != nil {
+		cacheWriter.Close()
+		return nil, err
+	}
+	if err := cacheWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	f.markFresh(path)
+	return f.cache.Open(path, os.O_RDONLY, 0)
+}
+
+// sameModTime compares remote's and cache's ResourceInfo.ModTime to decide
+// whether an expired cache entry can be kept without re-downloading.
+func (f *cacheOnReadFS) sameModTime(path Path) bool {
+	var remoteInfo, cacheInfo ResourceInfo
+	if err := f.remote.ReadAttrs(path, &remoteInfo); err != nil {
+		return false
+	}
+	if err := f.cache.ReadAttrs(path, &cacheInfo); err != nil {
+		return false
+	}
+	return remoteInfo.ModTime == cacheInfo.ModTime
+}
+
+func (f *cacheOnReadFS) isFresh(path Path) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	cachedAt, ok := f.cachedAt[path]
+	if !ok {
+		return false
+	}
+	return time.Since(cachedAt) < f.ttl
+}
+
+func (f *cacheOnReadFS) markFresh(path Path) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.cachedAt[path] = time.Now()
+}
+
+func (f *cacheOnReadFS) invalidate(path Path) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.cachedAt, path)
+}
+
+func (f *cacheOnReadFS) ReadDir(path Path, options interface{}) (DirEntList, error) {
+	return f.remote.ReadDir(path, options)
+}
+
+func (f *cacheOnReadFS) ReadAttrs(path Path, dst interface{}) error {
+	return f.remote.ReadAttrs(path, dst)
+}
+
+func (f *cacheOnReadFS) WriteAttrs(path Path, src interface{}) error {
+	f.invalidate(path)
+	return f.remote.WriteAttrs(path, src)
+}
+
+func (f *cacheOnReadFS) MkDirs(path Path) error {
+	return f.remote.MkDirs(path)
+}
+
+func (f *cacheOnReadFS) Rename(old, new Path) error {
+	f.invalidate(old)
+	f.invalidate(new)
+	return f.remote.Rename(old, new)
+}
+
+func (f *cacheOnReadFS) Link(old, new Path, mode LinkMode, flags int32) error {
+	f.invalidate(new)
+	return f.remote.Link(old, new, mode, flags)
+}
+
+func (f *cacheOnReadFS) Delete(path Path) error {
+	f.invalidate(path)
+	_ = f.cache.Delete(path)
+	return f.remote.Delete(path)
+}
+
+func (f *cacheOnReadFS) Close() error {
+	err := f.remote.Close()
+	if cacheErr := f.cache.Close(); err == nil {
+		err = cacheErr
+	}
+	return err
+}
@@ -0,0 +1,218 @@
+package vfsoverlay
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	. "github.com/worldiety/vfs"
+)
+
+// CopyOnWriteFS combines a read-only (or slower) base FileSystem with a writable
+// overlay. Reads are served from overlay first and fall through to base when
+// overlay does not have the path. Any write, delete or rename materializes its
+// target in overlay first, so base is never mutated.
+func CopyOnWriteFS(base, overlay FileSystem) FileSystem {
+	return &copyOnWriteFS{base: base, overlay: overlay, whiteout: map[Path]bool{}}
+}
+
+type copyOnWriteFS struct {
+	base     FileSystem
+	overlay  FileSystem
+	mutex    sync.RWMutex
+	whiteout map[Path]bool
+}
+
+// isWhitedOut reports whether path, or a directory above it, was deleted
+// through this FileSystem. vfs.FileSystem.Delete must remove an entry and
+// all its contained children, so a whiteout on a directory has to hide every
+// path beneath it too, not just the directory entry itself.
+func (f *copyOnWriteFS) isWhitedOut(path Path) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.whitedOutLocked(path)
+}
+
+// whitedOutLocked is isWhitedOut without acquiring f.mutex; callers must
+// already hold it for reading.
+func (f *copyOnWriteFS) whitedOutLocked(path Path) bool {
+	for w := range f.whiteout {
+		if w == path || strings.HasPrefix(path.String(), w.String()+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// revive removes every whiteout entry that currently hides path, whether it
+// whites out path itself or an ancestor directory of it. A plain
+// delete(f.whiteout, path) is not enough once whiteout is prefix-matched: a
+// write under a deleted directory must also undo that directory's whiteout,
+// or the new path stays hidden behind its still-whited-out ancestor forever.
+func (f *copyOnWriteFS) revive(path Path) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for w := range f.whiteout {
+		if w == path || strings.HasPrefix(path.String(), w.String()+"/") {
+			delete(f.whiteout, w)
+		}
+	}
+}
+
+func (f *copyOnWriteFS) whiteoutPath(path Path) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.whiteout[path] = true
+}
+
+// writeIntent reports whether flag would create or mutate the resource, as
+// opposed to merely reading it.
+func writeIntent(flag int) bool {
+	return flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE) != 0
+}
+
+func (f *copyOnWriteFS) Open(path Path, flag int, perm os.FileMode) (Resource, error) {
+	if writeIntent(flag) {
+		if err := f.materialize(path); err != nil {
+			return nil, err
+		}
+		res, err := f.overlay.Open(path, flag, perm)
+		if err != nil {
+			return nil, err
+		}
+		f.revive(path)
+		return res, nil
+	}
+
+	if f.isWhitedOut(path) {
+		return nil, &ResourceNotFoundError{Path: path}
+	}
+
+	if res, err := f.overlay.Open(path, flag, perm); err == nil {
+		return res, nil
+	}
+
+	return f.base.Open(path, flag, perm)
+}
+
+func (f *copyOnWriteFS) ReadDir(path Path, options interface{}) (DirEntList, error) {
+	var baseEntries []*PathEntry
+	if !f.isWhitedOut(path) {
+		if list, err := ReadDirOf(f.base, path); err == nil {
+			baseEntries = list
+		}
+	}
+
+	overlayEntries, err := ReadDirOf(f.overlay, path)
+	if err != nil && baseEntries == nil {
+		return nil, err
+	}
+
+	f.mutex.RLock()
+	merged := mergeEntries(baseEntries, overlayEntries, f.whitedOutLocked)
+	f.mutex.RUnlock()
+
+	return newSliceDirList(merged), nil
+}
+
+func (f *copyOnWriteFS) ReadAttrs(path Path, dst interface{}) error {
+	if f.isWhitedOut(path) {
+		return &ResourceNotFoundError{Path: path}
+	}
+
+	if err := f.overlay.ReadAttrs(path, dst); err == nil {
+		return nil
+	}
+
+	return f.base.ReadAttrs(path, dst)
+}
+
+// WriteAttrs always materializes into the overlay, copying the content from
+// base first if the overlay does not yet shadow this path.
+func (f *copyOnWriteFS) WriteAttrs(path Path, src interface{}) error {
+	if err := f.materialize(path); err != nil {
+		return err
+	}
+	return f.overlay.WriteAttrs(path, src)
+}
+
+func (f *copyOnWriteFS) MkDirs(path Path) error {
+	if err := f.overlay.MkDirs(path); err != nil {
+		return err
+	}
+	f.revive(path)
+	return nil
+}
+
+func (f *copyOnWriteFS) Rename(old, new Path) error {
+	if err := f.materialize(old); err != nil {
+		return err
+	}
+
+	if err := f.overlay.Rename(old, new); err != nil {
+		return err
+	}
+
+	f.whiteoutPath(old)
+	f.revive(new)
+	return nil
+}
+
+func (f *copyOnWriteFS) Link(old, new Path, mode LinkMode, flags int32) error {
+	if err := f.materialize(old); err != nil {
+		return err
+	}
+
+	if err := f.overlay.Link(old, new, mode, flags); err != nil {
+		return err
+	}
+
+	f.revive(new)
+	return nil
+}
+
+func (f *copyOnWriteFS) Delete(path Path) error {
+	_ = f.overlay.Delete(path)
+	f.whiteoutPath(path)
+	return nil
+}
+
+func (f *copyOnWriteFS) Close() error {
+	err := f.overlay.Close()
+	if baseErr := f.base.Close(); err == nil {
+		err = baseErr
+	}
+	return err
+}
+
+// materialize copies path from base into overlay if overlay does not already
+// shadow it, so that subsequent writes never touch base.
+func (f *copyOnWriteFS) materialize(path Path) error {
+	if f.isWhitedOut(path) {
+		return nil
+	}
+
+	if err := f.overlay.ReadAttrs(path, &ResourceInfo{}); err == nil {
+		return nil
+	}
+
+	reader, err := f.base.Open(path, os.O_RDONLY, 0)
+	if err != nil {
+		// nothing to materialize, path is new
+		return nil
+	}
+	defer reader.Close()
+
+	writer, err := f.overlay.Open(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(writer, reader); err != nil {
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
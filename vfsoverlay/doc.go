@@ -0,0 +1,5 @@
+// Package vfsoverlay provides vfs.FileSystem implementations that layer one or
+// more backing file systems into a single logical view, similar to afero's
+// composite filesystems. They are useful for making a slow or read-only backend
+// (e.g. the FTP backend in this module) usable for read-heavy or mixed workloads.
+package vfsoverlay
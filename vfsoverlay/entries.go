@@ -0,0 +1,102 @@
+package vfsoverlay
+
+import (
+	"fmt"
+
+	. "github.com/worldiety/vfs"
+)
+
+// sliceDirList is a DirEntList backed by an in-memory slice of entries, used
+// by the overlay implementations in this package to serve a merged directory
+// listing without re-querying the underlying file systems.
+type sliceDirList struct {
+	entries []*PathEntry
+	pos     int
+}
+
+func newSliceDirList(entries []*PathEntry) *sliceDirList {
+	return &sliceDirList{entries: entries, pos: -1}
+}
+
+func (s *sliceDirList) Next() bool {
+	s.pos++
+	return s.pos < len(s.entries)
+}
+
+func (s *sliceDirList) Scan(dst interface{}) error {
+	if s.pos < 0 || s.pos >= len(s.entries) {
+		return fmt.Errorf("vfsoverlay: Scan called without a successful Next")
+	}
+
+	info, ok := dst.(*ResourceInfo)
+	if !ok {
+		return &UnsupportedAttributesError{Data: dst}
+	}
+
+	*info = *s.entries[s.pos].Resource
+	return nil
+}
+
+func (s *sliceDirList) Err() error {
+	return nil
+}
+
+func (s *sliceDirList) Size() int64 {
+	return int64(len(s.entries))
+}
+
+func (s *sliceDirList) Close() error {
+	return nil
+}
+
+// ReadDirOf lists the direct children of path on fs, without requiring fs to
+// be installed as the package-level default FileSystem.
+func ReadDirOf(fs FileSystem, path Path) ([]*PathEntry, error) {
+	list, err := fs.ReadDir(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer list.Close()
+
+	var entries []*PathEntry
+	for list.Next() {
+		info := &ResourceInfo{}
+		if err := list.Scan(info); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &PathEntry{Path: path.Child(info.Name), Resource: info})
+	}
+
+	return entries, list.Err()
+}
+
+// mergeEntries overlays shadow on top of base: entries from shadow win by name,
+// entries from base for which whitedOut reports true are dropped entirely.
+// whitedOut may be nil, meaning nothing is whited out.
+func mergeEntries(base, shadow []*PathEntry, whitedOut func(Path) bool) []*PathEntry {
+	byName := make(map[string]*PathEntry, len(base)+len(shadow))
+	order := make([]string, 0, len(base)+len(shadow))
+
+	for _, entry := range base {
+		if whitedOut != nil && whitedOut(entry.Path) {
+			continue
+		}
+		if _, exists := byName[entry.Resource.Name]; !exists {
+			order = append(order, entry.Resource.Name)
+		}
+		byName[entry.Resource.Name] = entry
+	}
+
+	for _, entry := range shadow {
+		if _, exists := byName[entry.Resource.Name]; !exists {
+			order = append(order, entry.Resource.Name)
+		}
+		byName[entry.Resource.Name] = entry
+	}
+
+	merged := make([]*PathEntry, 0, len(order))
+	for _, name := range order {
+		merged = append(merged, byName[name])
+	}
+	return merged
+}
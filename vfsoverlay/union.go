@@ -0,0 +1,103 @@
+package vfsoverlay
+
+import (
+	"os"
+
+	. "github.com/worldiety/vfs"
+)
+
+// UnionFS merges several read-only layers into a single FileSystem. Layers are
+// searched in order, the first layer containing a given path wins both for
+// reads and for directory listings, where entries from earlier layers shadow
+// entries of the same name in later ones. UnionFS itself is read-only: Open
+// for writing, MkDirs, Rename, Link and WriteAttrs always fail.
+func UnionFS(layers []FileSystem) FileSystem {
+	return &unionFS{layers: layers}
+}
+
+type unionFS struct {
+	layers []FileSystem
+}
+
+const readOnlyMessage = "vfsoverlay: UnionFS is read-only"
+
+func (f *unionFS) Open(path Path, flag int, perm os.FileMode) (Resource, error) {
+	if writeIntent(flag) {
+		return nil, &UnsupportedOperationError{Message: readOnlyMessage}
+	}
+
+	var lastErr error
+	for _, layer := range f.layers {
+		res, err := layer.Open(path, os.O_RDONLY, 0)
+		if err == nil {
+			return res, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = &ResourceNotFoundError{Path: path}
+	}
+	return nil, lastErr
+}
+
+func (f *unionFS) ReadDir(path Path, options interface{}) (DirEntList, error) {
+	var merged []*PathEntry
+	var lastErr error
+	for i := len(f.layers) - 1; i >= 0; i-- {
+		entries, err := ReadDirOf(f.layers[i], path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		merged = mergeEntries(merged, entries, nil)
+	}
+	if merged == nil && lastErr != nil {
+		return nil, lastErr
+	}
+	return newSliceDirList(merged), nil
+}
+
+func (f *unionFS) ReadAttrs(path Path, dst interface{}) error {
+	var lastErr error
+	for _, layer := range f.layers {
+		if err := layer.ReadAttrs(path, dst); err == nil {
+			return nil
+		} else {
+			lastErr = err
+		}
+	}
+	if lastErr == nil {
+		lastErr = &ResourceNotFoundError{Path: path}
+	}
+	return lastErr
+}
+
+func (f *unionFS) WriteAttrs(Path, interface{}) error {
+	return &UnsupportedOperationError{Message: readOnlyMessage}
+}
+
+func (f *unionFS) MkDirs(Path) error {
+	return &UnsupportedOperationError{Message: readOnlyMessage}
+}
+
+func (f *unionFS) Rename(Path, Path) error {
+	return &UnsupportedOperationError{Message: readOnlyMessage}
+}
+
+func (f *unionFS) Link(Path, Path, LinkMode, int32) error {
+	return &UnsupportedOperationError{Message: readOnlyMessage}
+}
+
+func (f *unionFS) Delete(Path) error {
+	return &UnsupportedOperationError{Message: readOnlyMessage}
+}
+
+func (f *unionFS) Close() error {
+	var err error
+	for _, layer := range f.layers {
+		if cerr := layer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}